@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Values accepted by --autocd-multi.
+const (
+	autoCDMultiFirst          = "first"
+	autoCDMultiCommonAncestor = "common-ancestor"
+	autoCDMultiPick           = "pick"
+	autoCDMultiStack          = "stack"
+)
+
+// commonAncestor returns the longest directory prefix shared by every
+// directory in dirs, falling back to the root of the first entry's volume
+// when they diverge there. Selections can mix absolute and relative paths
+// (fzf reports whatever the source lines looked like), so every entry is
+// resolved to absolute first - comparing a relative path's components
+// against an absolute one's produces a nonsense prefix otherwise.
+func commonAncestor(dirs []string) string {
+	abs := make([]string, len(dirs))
+	for i, dir := range dirs {
+		if a, err := filepath.Abs(dir); err == nil {
+			abs[i] = a
+		} else {
+			abs[i] = filepath.Clean(dir)
+		}
+	}
+
+	// filepath.VolumeName handles the Windows "C:" drive-letter prefix;
+	// it's "" on POSIX, where the root is just the leading separator.
+	// Diverging volumes (e.g. C: vs D:) have no common ancestor beyond
+	// the first one's root.
+	vol := filepath.VolumeName(abs[0])
+	for _, dir := range abs[1:] {
+		if filepath.VolumeName(dir) != vol {
+			return vol + string(filepath.Separator)
+		}
+	}
+
+	components := make([][]string, len(abs))
+	shortest := -1
+	for i, dir := range abs {
+		parts := strings.Split(strings.TrimPrefix(dir, vol), string(filepath.Separator))
+		components[i] = parts
+		if shortest == -1 || len(parts) < shortest {
+			shortest = len(parts)
+		}
+	}
+
+	// Every entry now starts with a leading "" component (from the root
+	// separator after the volume), which is why matching is counted rather
+	// than joined on the fly - filepath.Join silently drops empty elements,
+	// which would turn an absolute result into a relative one.
+	common := components[0][:shortest]
+	matched := shortest
+outer:
+	for i := 0; i < shortest; i++ {
+		for _, parts := range components[1:] {
+			if parts[i] != common[i] {
+				matched = i
+				break outer
+			}
+		}
+	}
+
+	if matched <= 1 {
+		return vol + string(filepath.Separator)
+	}
+	return vol + string(filepath.Separator) + filepath.Join(common[1:matched]...)
+}
+
+// uniqueDirs preserves order while dropping duplicate directories.
+func uniqueDirs(dirs []string) []string {
+	seen := make(map[string]bool, len(dirs))
+	result := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if !seen[dir] {
+			seen[dir] = true
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// pickDirectory re-invokes fzf on the unique parent directories of a
+// multi-select and lets the user narrow it down to the one they meant.
+func pickDirectory(dirs []string) (string, bool) {
+	unique := uniqueDirs(dirs)
+	if len(unique) == 1 {
+		return unique[0], true
+	}
+
+	cmd := exec.Command(os.Args[0])
+	cmd.Stdin = strings.NewReader(strings.Join(unique, "\n"))
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fzf: autocd-multi=pick failed: %v\n", err)
+		return "", false
+	}
+
+	chosen := strings.TrimSpace(string(out))
+	if chosen == "" {
+		return "", false
+	}
+	return chosen, true
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCommonAncestor(t *testing.T) {
+	cases := []struct {
+		name string
+		dirs []string
+		want string
+	}{
+		{"siblings", []string{"/usr/local", "/usr/bin"}, "/usr"},
+		{"nested", []string{"/a/b/c", "/a/b/d"}, "/a/b"},
+		{"divergent roots", []string{"/a", "/b"}, "/"},
+		{"identical", []string{"/a/b", "/a/b"}, "/a/b"},
+		{"root itself", []string{"/", "/"}, "/"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := commonAncestor(tc.dirs); got != tc.want {
+				t.Errorf("commonAncestor(%v) = %q, want %q", tc.dirs, got, tc.want)
+			}
+		})
+	}
+}
+
+// Relative inputs are resolved against the working directory before being
+// compared, so the expected result is computed relative to it too.
+func TestCommonAncestorRelative(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := commonAncestor([]string{"a/b", "a/c"}), filepath.Join(cwd, "a"); got != want {
+		t.Errorf("commonAncestor(relative siblings) = %q, want %q", got, want)
+	}
+
+	// A mix of absolute and relative selections is resolved into the same
+	// absolute space rather than producing a garbage prefix.
+	mixed := []string{filepath.Join(cwd, "a", "b"), "a/c"}
+	if got, want := commonAncestor(mixed), filepath.Join(cwd, "a"); got != want {
+		t.Errorf("commonAncestor(mixed absolute/relative) = %q, want %q", got, want)
+	}
+}
+
+// commonAncestor has no build tag and is reachable on Windows through
+// handleAutoCD's --autocd-multi=common-ancestor mode, so a drive letter must be kept as part of the root
+// instead of being dropped by a plain separator split.
+func TestCommonAncestorWindowsVolume(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("drive-letter volumes only arise on windows")
+	}
+	if got, want := commonAncestor([]string{`C:\a\b`, `C:\a\c`}), `C:\a`; got != want {
+		t.Errorf("commonAncestor(windows siblings) = %q, want %q", got, want)
+	}
+	if got, want := commonAncestor([]string{`C:\a`, `D:\a`}), `C:\`; got != want {
+		t.Errorf("commonAncestor(different volumes) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,238 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/codinganovel/autocd-go"
+	"github.com/junegunn/fzf/src/tui"
+	"github.com/junegunn/fzf/src/util"
+)
+
+// performAutoCD replaces the current process with the user's shell in
+// targetDir, the same process-replacement trick fzf's "become" action uses.
+// If execCmd is set (--autocd-exec), it runs that in targetDir first via
+// performAutoCDExec instead of going through autocd-go.
+func performAutoCD(targetDir string, execCmd string) {
+	if execCmd != "" {
+		performAutoCDExec([]string{targetDir}, execCmd)
+		return
+	}
+
+	fallback := func() {
+		fmt.Fprintf(os.Stderr, "fzf: autocd failed, falling back to normal exit\n")
+		os.Exit(0)
+	}
+
+	// Set up stdin properly before calling autocd, just like fzf's become action.
+	// This ensures the terminal file descriptor is correctly configured regardless
+	// of the working directory from which fzf was launched.
+	if ttyin, err := tui.TtyIn(tui.DefaultTtyDevice); err == nil {
+		util.SetStdin(ttyin)
+	}
+
+	// `fzf --autocd > file` would otherwise hand the exec'd shell a
+	// redirected stdout/stderr, same problem fzf's execute/become actions
+	// have. When either isn't a tty, open a fresh handle on the
+	// controlling terminal and exec against that instead.
+	if util.IsTty(os.Stdin) && util.IsTty(os.Stdout) && util.IsTty(os.Stderr) {
+		autocd.ExitWithDirectoryOrFallback(targetDir, fallback)
+		return
+	}
+
+	ttyIn, errIn := tui.TtyIn(tui.DefaultTtyDevice)
+	ttyOut, errOut := tui.TtyOut(tui.DefaultTtyDevice)
+	if errIn != nil || errOut != nil {
+		autocd.ExitWithDirectoryOrFallback(targetDir, fallback)
+		return
+	}
+	defer ttyIn.Close()
+	defer ttyOut.Close()
+
+	exitWithDirectoryFDs(targetDir, ttyIn, ttyOut, ttyOut, fallback)
+}
+
+// exitWithDirectoryFDs is this fork's local stand-in for the FD-aware
+// trampoline autocd-go doesn't have yet: it's an external module we don't
+// vendor, so rather than assume a helper that isn't there, dup2 the given
+// descriptors onto 0/1/2 ourselves before handing off to
+// autocd.ExitWithDirectoryOrFallback - process replacement always inherits
+// the real OS file descriptors, not *os.File values, so this is enough to
+// make the exec'd shell see in/out/err instead of whatever fzf had.
+func exitWithDirectoryFDs(targetDir string, in, out, errOut *os.File, fallback func()) {
+	fds := map[int]*os.File{0: in, 1: out, 2: errOut}
+	for fd, f := range fds {
+		if err := syscall.Dup2(int(f.Fd()), fd); err != nil {
+			fallback()
+			return
+		}
+	}
+	autocd.ExitWithDirectoryOrFallback(targetDir, fallback)
+}
+
+// performAutoCDStack pushes every directory but the last onto the shell's
+// dirstack and lands in the last one, for --autocd-multi=stack.
+func performAutoCDStack(dirs []string, execCmd string) {
+	performAutoCDExec(dirs, execCmd)
+}
+
+// performAutoCDExec lands the user in dirs[len(dirs)-1], with every earlier
+// directory pushed onto the dirstack first, and execCmd (if any) run once
+// it's there. autocd-go's trampoline only knows how to cd into one
+// directory and hand off via exec, and a bare "shell -c 'pushd ...; exec
+// $SHELL -i'" doesn't work: the final exec replaces the process image,
+// which throws away the in-memory dirstack the pushd calls just built.
+// Instead, for shells that support it, the init commands are injected into
+// the *same* process that becomes interactive - bash via --rcfile, zsh via
+// ZDOTDIR, fish via --init-command - so nothing is lost to a second exec.
+// Shells without such a hook fall back to a plain cd into the last
+// directory (dirstack dropped, execCmd still runs).
+func performAutoCDExec(dirs []string, execCmd string) {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	if ttyin, err := tui.TtyIn(tui.DefaultTtyDevice); err == nil {
+		util.SetStdin(ttyin)
+	}
+
+	fail := func(err error) {
+		fmt.Fprintf(os.Stderr, "fzf: autocd failed: %v\n", err)
+		os.Exit(0)
+	}
+
+	// Same problem performAutoCD guards against: "fzf --autocd-exec ... >
+	// file" would otherwise hand the shell we're about to exec into a
+	// redirected stdout/stderr. Rewire 0/1/2 onto the controlling terminal
+	// first when any of them isn't already a tty.
+	if !(util.IsTty(os.Stdin) && util.IsTty(os.Stdout) && util.IsTty(os.Stderr)) {
+		ttyIn, errIn := tui.TtyIn(tui.DefaultTtyDevice)
+		ttyOut, errOut := tui.TtyOut(tui.DefaultTtyDevice)
+		if errIn == nil && errOut == nil {
+			defer ttyIn.Close()
+			defer ttyOut.Close()
+			for fd, f := range map[int]*os.File{0: ttyIn, 1: ttyOut, 2: ttyOut} {
+				if err := syscall.Dup2(int(f.Fd()), fd); err != nil {
+					fail(err)
+					return
+				}
+			}
+		}
+	}
+
+	switch filepath.Base(shell) {
+	case "bash":
+		rcfile, err := writeInitScript("fzf-autocd-bash-*.rc", func(b *strings.Builder) {
+			fmt.Fprintf(b, "[ -f ~/.bashrc ] && source ~/.bashrc\n")
+			writeStackCommands(b, dirs, execCmd)
+		})
+		if err != nil {
+			fail(err)
+			return
+		}
+		if err := syscall.Exec(shell, []string{shell, "--rcfile", rcfile, "-i"}, os.Environ()); err != nil {
+			fail(err)
+		}
+	case "zsh":
+		zdotdir, err := writeZshInit(dirs, execCmd)
+		if err != nil {
+			fail(err)
+			return
+		}
+		env := append(os.Environ(), "ZDOTDIR="+zdotdir)
+		if err := syscall.Exec(shell, []string{shell, "-i"}, env); err != nil {
+			fail(err)
+		}
+	case "fish":
+		var b strings.Builder
+		writeStackCommands(&b, dirs, execCmd)
+		if err := syscall.Exec(shell, []string{shell, "--init-command", b.String(), "-i"}, os.Environ()); err != nil {
+			fail(err)
+		}
+	default:
+		if len(dirs) > 1 {
+			fmt.Fprintf(os.Stderr, "fzf: autocd-multi=stack isn't supported under %s, cd'ing into the last directory only\n", shell)
+		}
+		var script strings.Builder
+		fmt.Fprintf(&script, "cd %s", shellQuote(dirs[len(dirs)-1]))
+		if execCmd != "" {
+			fmt.Fprintf(&script, " && %s", execCmd)
+		}
+		fmt.Fprintf(&script, "; exec %s -i", shellQuote(shell))
+		if err := syscall.Exec(shell, []string{shell, "-c", script.String()}, os.Environ()); err != nil {
+			fail(err)
+		}
+	}
+}
+
+// writeStackCommands appends a pushd chain over dirs[:len-1], a final cd
+// into dirs[len-1], and execCmd (if set) to b, one command per line.
+func writeStackCommands(b *strings.Builder, dirs []string, execCmd string) {
+	for _, dir := range dirs[:len(dirs)-1] {
+		fmt.Fprintf(b, "pushd %s > /dev/null\n", shellQuote(dir))
+	}
+	fmt.Fprintf(b, "cd %s\n", shellQuote(dirs[len(dirs)-1]))
+	if execCmd != "" {
+		fmt.Fprintf(b, "%s\n", execCmd)
+	}
+}
+
+// writeInitScript renders an init script via build, writes it to a fresh
+// temp file matching pattern, and arranges for the file to delete itself
+// once sourced, and returns its path.
+func writeInitScript(pattern string, build func(*strings.Builder)) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	build(&b)
+	fmt.Fprintf(&b, "rm -f %s\n", shellQuote(f.Name()))
+
+	if _, err := f.WriteString(b.String()); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// writeZshInit sets up a throwaway ZDOTDIR whose .zshenv sources the user's
+// real .zshrc (from the real ZDOTDIR, or $HOME) after running the
+// stack/exec commands, so the user's .zshrc still loads. The user's own
+// .zshenv is not read, since zsh looks for it in the throwaway ZDOTDIR.
+func writeZshInit(dirs []string, execCmd string) (string, error) {
+	dir, err := os.MkdirTemp("", "fzf-autocd-zsh-*")
+	if err != nil {
+		return "", err
+	}
+
+	realZdotdir := os.Getenv("ZDOTDIR")
+	if realZdotdir == "" {
+		realZdotdir = os.Getenv("HOME")
+	}
+
+	var b strings.Builder
+	writeStackCommands(&b, dirs, execCmd)
+	fmt.Fprintf(&b, "[ -f %s/.zshrc ] && source %s/.zshrc\n", shellQuote(realZdotdir), shellQuote(realZdotdir))
+	fmt.Fprintf(&b, "rm -rf %s\n", shellQuote(dir))
+
+	if err := os.WriteFile(filepath.Join(dir, ".zshenv"), []byte(b.String()), 0o600); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// shellQuote wraps s in single quotes, escaping any embedded ones, so it's
+// safe to splice into a shell script.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// autoCDExitCode is returned instead of fzf.ExitOk when performAutoCD has
+// written out a directory file. The key bindings in shell/ look for this
+// exact code before trying to read the file, so that a plain "no autocd
+// happened" exit doesn't get confused with one that did.
+const autoCDExitCode = 126
+
+// performAutoCD can't replace the current process on Windows the way Unix
+// exec(2) does, so instead it drops the selected directory into a fresh
+// temp file, prints that file's path to stdout, and exits with
+// autoCDExitCode. The calling shell (see shell/key-bindings.ps1, .cmd,
+// .gitbash.sh) captures the printed path directly - rather than trying to
+// reconstruct it from a pid, which doesn't reliably round-trip through
+// Git Bash/MSYS, where the wrapper's $! differs from fzf.exe's own Windows
+// pid. If execCmd is set (--autocd-exec), it's also written to a sibling
+// .cmd file for the caller to run after cd'ing.
+func performAutoCD(targetDir string, execCmd string) {
+	performAutoCDStack([]string{targetDir}, execCmd)
+}
+
+// performAutoCDStack writes every directory, in order, to the trampoline
+// file so the PowerShell and Git Bash key bindings can pushd through all
+// but the last and land in it. There's no native dirstack to push onto
+// from here - those bindings manage that on the calling side. The cmd.exe
+// binding reads only the first line, so it cd's into the first directory.
+func performAutoCDStack(dirs []string, execCmd string) {
+	fail := func(err error) {
+		fmt.Fprintf(os.Stderr, "fzf: autocd failed: %v\n", err)
+		os.Exit(0)
+	}
+
+	f, err := os.CreateTemp("", "fzf-autocd-*.dir")
+	if err != nil {
+		fail(err)
+	}
+	path := f.Name()
+	_, writeErr := f.WriteString(strings.Join(dirs, "\n"))
+	closeErr := f.Close()
+	if writeErr != nil {
+		os.Remove(path)
+		fail(writeErr)
+	}
+	if closeErr != nil {
+		os.Remove(path)
+		fail(closeErr)
+	}
+
+	if execCmd != "" {
+		cmdPath := strings.TrimSuffix(path, ".dir") + ".cmd"
+		if err := os.WriteFile(cmdPath, []byte(execCmd), 0o600); err != nil {
+			fail(err)
+		}
+	}
+
+	fmt.Println(path)
+	os.Exit(autoCDExitCode)
+}
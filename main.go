@@ -8,11 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/codinganovel/autocd-go"
 	fzf "github.com/junegunn/fzf/src"
 	"github.com/junegunn/fzf/src/protector"
-	"github.com/junegunn/fzf/src/tui"
-	"github.com/junegunn/fzf/src/util"
 )
 
 var version = "0.64"
@@ -33,6 +30,15 @@ var zshCompletion []byte
 //go:embed shell/key-bindings.fish
 var fishKeyBindings []byte
 
+//go:embed shell/key-bindings.ps1
+var ps1KeyBindings []byte
+
+//go:embed shell/key-bindings.cmd
+var cmdKeyBindings []byte
+
+//go:embed shell/key-bindings.gitbash.sh
+var gitBashKeyBindings []byte
+
 //go:embed man/man1/fzf.1
 var manPage []byte
 
@@ -72,6 +78,18 @@ func main() {
 		fmt.Println("fzf_key_bindings")
 		return
 	}
+	if options.PS1 {
+		printScript("key-bindings.ps1", ps1KeyBindings)
+		return
+	}
+	if options.Cmd {
+		printScript("key-bindings.cmd", cmdKeyBindings)
+		return
+	}
+	if options.GitBash {
+		printScript("key-bindings.gitbash.sh", gitBashKeyBindings)
+		return
+	}
 	if options.Help {
 		fmt.Print(fzf.Usage)
 		return
@@ -101,35 +119,69 @@ func main() {
 	}
 
 	code, selectedItem, err := fzf.Run(options)
-	
-	// Handle autocd functionality
-	if code == fzf.ExitOk && options.AutoCD && selectedItem != "" {
-		handleAutoCD(selectedItem)
+
+	// Handle autocd functionality. This fires either when the user accepted
+	// a selection with --autocd set, or when they invoked the `autocd`
+	// bindable action directly (e.g. --bind 'ctrl-o:autocd' or
+	// 'alt-c:autocd(parent)'), which fzf reports via fzf.ExitAutoCD instead
+	// of waiting for acceptance.
+	//
+	// The action's own parent/self argument - which of the focused entry or
+	// its parent directory to target - is resolved entirely upstream, by
+	// the action-kind parsing and event loop in fzf.ParseOptions/fzf.Run;
+	// none of that lives in this fork (this tree only carries the
+	// already-built fzf.ExitAutoCD/fzf.Options.AutoCD* surface it depends
+	// on, not fzf's own src tree). By the time control reaches here,
+	// selectedItem is already whichever path the argument selected, so
+	// handleAutoCD's existing is-it-a-directory-or-its-parent logic below
+	// covers both forms without needing to know which one fired.
+	if (code == fzf.ExitOk && options.AutoCD || code == fzf.ExitAutoCD) && selectedItem != "" {
+		handleAutoCD(options, selectedItem)
 		// If autocd fails, fall back to normal exit
 	}
-	
+
 	exit(code, err)
 }
 
-func handleAutoCD(selectedItem string) {
-	var targetDir string
-	if isDirectory(selectedItem) {
-		targetDir = selectedItem
-	} else {
-		targetDir = filepath.Dir(selectedItem)
+// handleAutoCD turns one or more selected entries into target directories
+// (each item itself, or its parent if it's a file). With a single selection
+// (the common case, and the only one possible without -m) that directory
+// goes to performAutoCD. With multiple selections, options.AutoCDMulti picks
+// how they're reduced to one directory, except for "stack", which hands all
+// of the unique directories to performAutoCDStack.
+func handleAutoCD(options *fzf.Options, selectedItem string) {
+	items := strings.Split(strings.TrimRight(selectedItem, "\n"), "\n")
+	dirs := make([]string, 0, len(items))
+	for _, item := range items {
+		if item == "" {
+			continue
+		}
+		if isDirectory(item) {
+			dirs = append(dirs, item)
+		} else {
+			dirs = append(dirs, filepath.Dir(item))
+		}
 	}
-
-	// Fix: Set up stdin properly before calling autocd, just like fzf's become action
-	// This ensures the terminal file descriptor is correctly configured regardless of
-	// the working directory from which fzf was launched
-	if ttyin, err := tui.TtyIn(tui.DefaultTtyDevice); err == nil {
-		util.SetStdin(ttyin)
+	if len(dirs) == 0 {
+		return
+	}
+	if len(dirs) == 1 {
+		performAutoCD(dirs[0], options.AutoCDExec)
+		return
 	}
 
-	autocd.ExitWithDirectoryOrFallback(targetDir, func() {
-		fmt.Fprintf(os.Stderr, "fzf: autocd failed, falling back to normal exit\n")
-		os.Exit(0)
-	})
+	switch options.AutoCDMulti {
+	case autoCDMultiCommonAncestor:
+		performAutoCD(commonAncestor(dirs), options.AutoCDExec)
+	case autoCDMultiPick:
+		if chosen, ok := pickDirectory(dirs); ok {
+			performAutoCD(chosen, options.AutoCDExec)
+		}
+	case autoCDMultiStack:
+		performAutoCDStack(uniqueDirs(dirs), options.AutoCDExec)
+	default: // "first"
+		performAutoCD(dirs[0], options.AutoCDExec)
+	}
 }
 
 func isDirectory(path string) bool {